@@ -1,16 +1,25 @@
 package main
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	u "goprac/utils"
-	"log"
-	"math"
-	"math/rand"
+	"math/big"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
 const gameDuration = 60
 
+// roundCountdown is how long StartGame counts down before a round ends.
+// defaultIdleThreshold (presence.go) is derived from this so the two
+// can't drift back out of sync - see StartGame.
+const roundCountdown = 30 * time.Second
+
 const (
 	Idle       = 0
 	InProgress = 1
@@ -28,6 +37,27 @@ type Game struct {
 	State     int        `json:"state"`
 	BetsMutex *sync.Mutex
 	StateMutex *sync.Mutex
+
+	// SeedHash is published before the round starts (the "seed-commit"
+	// event) so players can verify after the fact that serverSeed,
+	// revealed once bets close, wasn't swapped out. RevealedSeed is the
+	// secret itself, filled in by EndGame (and again by GetWinner) once
+	// it's safe to disclose, and exported so it actually goes out over the wire on
+	// the "seed-reveal"/"end-game" events - without that, nobody outside
+	// the process could ever recompute drawWinningTicket.
+	SeedHash     string `json:"seedHash,omitempty"`
+	RevealedSeed string `json:"revealedSeed,omitempty"`
+	serverSeed   []byte
+
+	// WinnerPlayerID is set once GetWinner picks a winner, so the rest
+	// of the round's lifecycle (and the GameStore) don't have to
+	// re-derive it.
+	WinnerPlayerID int `json:"winnerPlayerId,omitempty"`
+
+	// tournament is set when this game is one match of a bracket; it
+	// tells EndGame to hand off scheduling to the Tournament instead of
+	// auto-starting a fresh standalone game.
+	tournament *Tournament
 }
 
 type GameManager struct {
@@ -35,6 +65,10 @@ type GameManager struct {
 	pastGames   map[int64]Game
 	currentGame Game
 	events      chan interface{}
+	settler     Settler
+	presence    *PlayerPresence
+	rng         RNG
+	store       GameStore
 }
 
 type Player struct {
@@ -50,11 +84,11 @@ func NewPlayer(uid int, email string) *Player {
 }
 
 type Bet struct {
-	Amount  float64 `json:"amount"`
+	Amount  Money `json:"amount"`
 	Created time.Time
 }
 
-func NewBet(amount float64) *Bet {
+func NewBet(amount Money) *Bet {
 	return &Bet{
 		Amount:  amount,
 		Created: time.Now(),
@@ -64,8 +98,8 @@ func NewBet(amount float64) *Bet {
 type UserBet struct {
 	Bets   []*Bet  `json:"bets"`
 	Player *Player `json:"player"`
-	StartTicket int `json:"startTicket"`
-	EndTicket int `json:"endTicket"`
+	StartTicket int64 `json:"startTicket"`
+	EndTicket int64 `json:"endTicket"`
 	Share  float64 `json:"share"`
 }
 
@@ -76,9 +110,9 @@ func NewUserBet(bet *Bet, player *Player) *UserBet {
 	}
 }
 
-func (ub UserBet) GetTotalBet() (total float64) {
+func (ub UserBet) GetTotalBet() (total Money) {
 	for _, bet := range ub.Bets {
-		total = total + bet.Amount
+		total += bet.Amount
 	}
 	return
 }
@@ -88,13 +122,41 @@ func NewGameManager() *GameManager {
 		mutex:     sync.Mutex{},
 		pastGames: make(map[int64]Game),
 		events:    make(chan interface{}),
+		settler:   NewMemorySettler(),
+		presence:  NewPlayerPresence(defaultIdleThreshold),
+		rng:       CryptoRNG{},
+		store:     NewMemoryGameStore(),
 	}
 }
 
+// SetRNG swaps the randomness source used to generate server seeds -
+// tests use this to inject a SeededRNG so GetWinner is reproducible.
+func (gm *GameManager) SetRNG(rng RNG) {
+	gm.rng = rng
+}
+
+// SetStore swaps the game history persistence backend, e.g. for a
+// GORMGameStore once a *gorm.DB is available; the default keeps games
+// in memory only, same as before this existed.
+func (gm *GameManager) SetStore(store GameStore) {
+	gm.store = store
+}
+
 func (gm *GameManager) Events() chan interface{} {
 	return gm.events
 }
 
+// Touch records activity for a player, e.g. a websocket ping, a bet, or
+// any other authenticated request.
+func (gm *GameManager) Touch(playerID int) {
+	gm.presence.Touch(playerID)
+}
+
+// Logger returns a logger scoped to the currently running game.
+func (gm *GameManager) Logger() *GameLogger {
+	return gm.currentGame.Logger()
+}
+
 func (gm *GameManager) NewGame() {
 	gm.mutex.Lock()
 	now := time.Now()
@@ -119,8 +181,8 @@ func (gm *GameManager) NewGame() {
 		Game: newGame,
 	}
 
-	log.Println("[GAME] New game started")
-	log.Println("[GAME] Waiting for bets from at least 2 ppl..")
+	newGame.Logger().Println("New game started")
+	newGame.Logger().Println("Waiting for bets from at least 2 ppl..")
 }
 
 func (gm *GameManager) GetCurrentGame() *Game {
@@ -134,8 +196,17 @@ func (gm *GameManager) StartGame() {
 	gm.mutex.Lock()
 	gm.currentGame.StartTime = time.Now()
 
+	if err := gm.currentGame.commitSeed(); err != nil {
+		gm.Logger().Printf("failed to commit seed: %v", err)
+	}
+
 	gm.currentGame.SetState(InProgress)
 
+	gm.events <- GameEvent{
+		Type: "seed-commit",
+		Game: gm.currentGame,
+	}
+
 	gm.events <- GameEvent{
 		Type: "start-game",
 		Game: gm.currentGame,
@@ -143,18 +214,64 @@ func (gm *GameManager) StartGame() {
 
 	gm.mutex.Unlock()
 
-	log.Println("[GAME] Game Started")
+	gm.Logger().Println("Game Started")
 
 	defer func() {
-		for d := range u.Countdown(u.NewTicker(time.Second), 30*time.Second) {
+		for d := range u.Countdown(u.NewTicker(time.Second), roundCountdown) {
 			gm.events <- CountDownEvent{
 				TimeLeft: d.Seconds(),
 			}
 		}
+
+		if gm.currentGame.activeBettorCount() < 2 {
+			gm.RefundGame()
+			return
+		}
+
 		gm.EndGame()
 	}()
 }
 
+// RefundGame cancels the current round and hands every escrowed bet
+// back through the settlement adapter, for when too few bettors are
+// still active to fairly pick a winner.
+func (gm *GameManager) RefundGame() {
+	gm.mutex.Lock()
+
+	gm.currentGame.EndTime = time.Now()
+	gm.currentGame.SetState(Ended)
+	gm.pastGames[gm.currentGame.ID] = gm.currentGame
+
+	// Cancelled rounds are recorded in history too, same as completed
+	// ones (GetWinner), so a refunded round doesn't just vanish from the
+	// store on restart.
+	if err := gm.store.Save(gm.currentGame); err != nil {
+		gm.Logger().Printf("failed to save cancelled game history: %v", err)
+	}
+
+	for _, userBet := range gm.currentGame.UserBets {
+		amount := userBet.GetTotalBet()
+		if err := gm.settler.Refund(userBet.Player, amount); err != nil {
+			gm.Logger().Printf("refund failed for player %d: %v", userBet.Player.Id, err)
+		}
+	}
+
+	gm.events <- GameEvent{
+		Type: "game-cancelled",
+		Game: gm.currentGame,
+	}
+
+	gm.mutex.Unlock()
+
+	gm.Logger().Print("cancelled, not enough active players - bets refunded")
+
+	defer func() {
+		gm.Logger().Println("starting new game in 5 seconds...")
+		time.Sleep(time.Second * 5)
+		gm.NewGame()
+	}()
+}
+
 func (gm *GameManager) EndGame() {
 	gm.mutex.Lock()
 
@@ -163,6 +280,13 @@ func (gm *GameManager) EndGame() {
 
 	gm.currentGame.SetState(Ended)
 
+	// Saving the game history happens once, in GetWinner below, once
+	// CalculateShares/GetWinner have filled in the final StartTicket,
+	// EndTicket and WinnerPlayerID - saving here too would both persist
+	// pre-winner zeroed ticket data and double-insert every bet row.
+
+	gm.currentGame.RevealedSeed = hex.EncodeToString(gm.currentGame.serverSeed)
+
 	gm.events <- GameEvent{
 		Type: "end-game",
 		Game: gm.currentGame,
@@ -170,11 +294,16 @@ func (gm *GameManager) EndGame() {
 
 	gm.mutex.Unlock()
 
-	log.Print("[GAME] Has ended, no more bets!")
-	_ = gm.currentGame.GetWinner()
+	gm.Logger().Print("Has ended, no more bets!")
+	winner := gm.currentGame.GetWinner()
+
+	if t := gm.currentGame.tournament; t != nil {
+		t.advance(&gm.currentGame, winner)
+		return
+	}
 
 	defer func() {
-		log.Println("[GAME] starting new game in 5 seconds...")
+		gm.Logger().Println("starting new game in 5 seconds...")
 		time.Sleep(time.Second * 5)
 		gm.NewGame()
 	}()
@@ -186,8 +315,9 @@ func (g *Game) SetState(state int) {
 	g.State = state
 }
 
-func (g *Game) PlaceBet(player *Player, amount float64) {
-	log.Printf("[GAME] NEW BET:($%.2f) FROM => Id: %d ", amount, player.Id)
+func (g *Game) PlaceBet(player *Player, amount Money) {
+	g.Logger().Printf("NEW BET:(%s) FROM => Id: %d ", amount, player.Id)
+	gameManager.Touch(player.Id)
 	g.BetsMutex.Lock()
 
 	bet := NewBet(amount)
@@ -207,42 +337,73 @@ func (g *Game) PlaceBet(player *Player, amount float64) {
 
 	g.BetsMutex.Unlock()
 
+	if err := gameManager.settler.Escrow(player, amount); err != nil {
+		g.Logger().Printf("escrow failed for player %d: %v", player.Id, err)
+	}
+
 	gameManager.events <- GameEvent{
 		Type:   "bet-placed",
 		Game:   *gameManager.GetCurrentGame(),
 		Player: player,
-		Amount: amount,
+		Amount: amount.Dollars(),
 	}
 
-	log.Printf("[GAME] TOTAL BETS:($%.2f) ", g.GetTotalPrice())
+	g.Logger().Printf("TOTAL BETS:(%s) ", g.GetTotalPrice())
 
-	if g.StartTime.IsZero() && len(g.UserBets) >= 2 {
-		log.Print("[GAME] Enough players starting game...")
+	if g.StartTime.IsZero() && g.activeBettorCount() >= 2 {
+		g.Logger().Print("Enough players starting game...")
 		go gameManager.StartGame()
 	}
 }
 
-func (g Game) GetTotalPrice() (totalPrice float64) {
+// activeBettorCount returns how many distinct players with a bet placed
+// this round have been seen within the idle threshold - idle bettors
+// don't count towards starting (or keeping alive) a round.
+func (g Game) activeBettorCount() int {
+	count := 0
+	for _, ub := range g.UserBets {
+		if gameManager.presence.IsActive(ub.Player.Id) {
+			count++
+		}
+	}
+	return count
+}
+
+// hasPlayer reports whether playerID placed a bet in this game.
+func (g Game) hasPlayer(playerID int) bool {
+	for _, ub := range g.UserBets {
+		if ub.Player.Id == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+func (g Game) GetTotalPrice() (totalPrice Money) {
 	for _, userBet := range g.UserBets {
 		for _, bet := range userBet.Bets {
-			totalPrice = totalPrice + bet.Amount
+			totalPrice += bet.Amount
 		}
 	}
 	return
 }
 
-func (g Game) GetTotalPriceOfUsers() (pricePerUser map[int]float64) {
-	pricePerUser = make(map[int]float64)
+func (g Game) GetTotalPriceOfUsers() (pricePerUser map[int]Money) {
+	pricePerUser = make(map[int]Money)
 
 	for _, userBet := range g.UserBets {
 		for _, bet := range userBet.Bets {
-			pricePerUser[userBet.Player.Id] = pricePerUser[userBet.Player.Id] + bet.Amount
+			pricePerUser[userBet.Player.Id] += bet.Amount
 		}
 	}
 	return
 }
 
-
+// CalculateShares assigns each UserBet a contiguous, gapless range of
+// ticket cents - [StartTicket, EndTicket] - covering exactly their
+// share of the pot, plus the Share percentage for display. Everything
+// here works in Money (int64 cents) so fractional-dollar bets aren't
+// truncated away.
 func (g *Game) CalculateShares() {
 	g.BetsMutex.Lock()
 	defer func() {
@@ -253,43 +414,122 @@ func (g *Game) CalculateShares() {
 		g.BetsMutex.Unlock()
 	}()
 
-	// using cents to increase accuracy of 'user-tickets'.
-	total := math.Round(g.GetTotalPrice())
-	totalCents := total * 100
-	startTicket := 0
+	totalCents := int64(g.GetTotalPrice())
+	startTicket := int64(0)
 
-	log.Printf("[CALC-SHARES] Total pot: $%f", total)
+	g.Logger().Printf("[CALC-SHARES] Total pot: %s", g.GetTotalPrice())
 
-	for _, ub := range g.UserBets {
-		betInCents := int(ub.GetTotalBet()) * 100
+	for i := range g.UserBets {
+		ub := &g.UserBets[i]
+		betInCents := int64(ub.GetTotalBet())
 		ub.StartTicket = startTicket
-		ub.EndTicket = startTicket + betInCents
-		ub.Share = (100 / totalCents) * float64(betInCents)
+		ub.EndTicket = startTicket + betInCents - 1
+		if totalCents > 0 {
+			ub.Share = 100 * float64(betInCents) / float64(totalCents)
+		}
 
-		startTicket += betInCents +1
-		log.Printf("[CALC-SHARES] User: %d | StartTicket: %d | EndTicket: %d | Share: %f |", ub.Player.Id, ub.StartTicket, ub.EndTicket, ub.Share)
+		startTicket += betInCents
+		g.Logger().Printf("[CALC-SHARES] User: %d | StartTicket: %d | EndTicket: %d | Share: %f |", ub.Player.Id, ub.StartTicket, ub.EndTicket, ub.Share)
 	}
 }
 
-func (g *Game) GetWinner()  {
-	log.Print("[GAME] picking a winner...")
+// commitSeed generates a fresh provably-fair server seed for the round
+// and records only its SHA-256 hash on the game; the seed itself stays
+// secret until GetWinner reveals it.
+func (g *Game) commitSeed() error {
+	seed := make([]byte, 32)
+	if _, err := gameManager.rng.Read(seed); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(seed)
+	g.serverSeed = seed
+	g.SeedHash = hex.EncodeToString(sum[:])
+	return nil
+}
+
+// drawWinningTicket derives the winning ticket from the committed server
+// seed instead of a fresh random draw, so anyone who knows the seed and
+// the bets can recompute the same result and verify the round wasn't
+// manipulated. totalTickets must be positive - callers must not draw on
+// an empty pot.
+func (g Game) drawWinningTicket(totalTickets int64) int64 {
+	mac := hmac.New(sha256.New, g.serverSeed)
+	mac.Write([]byte(fmt.Sprintf("%d|%s", g.ID, g.sortedBetsFingerprint())))
+	sum := mac.Sum(nil)
+
+	draw := new(big.Int).SetBytes(sum)
+	return draw.Mod(draw, big.NewInt(totalTickets)).Int64()
+}
+
+// sortedBetsFingerprint concatenates the userBets in a deterministic
+// (player-id-sorted) order so the winning ticket derivation doesn't
+// depend on the order bets happened to be placed in.
+func (g Game) sortedBetsFingerprint() string {
+	bets := make([]UserBet, len(g.UserBets))
+	copy(bets, g.UserBets)
+	sort.Slice(bets, func(i, j int) bool { return bets[i].Player.Id < bets[j].Player.Id })
+
+	var sb strings.Builder
+	for _, ub := range bets {
+		fmt.Fprintf(&sb, "%d:%d;", ub.Player.Id, int64(ub.GetTotalBet()))
+	}
+	return sb.String()
+}
+
+// GetWinner picks (and pays out) the round's winner, returning their
+// UserBet so callers - e.g. a Tournament advancing its bracket - know
+// who it was. Returns nil if the pot is empty, or if no bet's ticket
+// range covered the draw, which shouldn't happen but isn't this
+// method's place to panic over.
+func (g *Game) GetWinner() *UserBet {
+	g.Logger().Print("picking a winner...")
 	g.CalculateShares() // extra safety, might be unnecessary -.-'
 	g.BetsMutex.Lock()
 	defer g.BetsMutex.Unlock()
 
-	totalTickets := int(math.Round(g.GetTotalPrice()) * 100)
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	winningTicket := r.Intn(totalTickets)
+	totalTickets := int64(g.GetTotalPrice())
+	if totalTickets <= 0 {
+		g.Logger().Print("no bets in pot, nothing to draw a winner from")
+		return nil
+	}
+	winningTicket := g.drawWinningTicket(totalTickets)
 
-	for _, userBet := range g.UserBets {
+	g.RevealedSeed = hex.EncodeToString(g.serverSeed)
+
+	gameManager.events <- GameEvent{
+		Type: "seed-reveal",
+		Game: *g,
+	}
+
+	for i := range g.UserBets {
+		userBet := &g.UserBets[i]
 		if userBet.StartTicket <= winningTicket && userBet.EndTicket >= winningTicket {
 			g.SetState(WinnerPicked)
+			g.WinnerPlayerID = userBet.Player.Id
+			pot := g.GetTotalPrice()
+			netWinnings := pot - userBet.GetTotalBet()
+
+			// Every bettor, winner included, was escrowed their own stake
+			// in PlaceBet, so the winner must be paid the full pot (not
+			// pot-minus-their-own-stake) for escrow+payout to net to zero.
+			if err := gameManager.settler.Payout(userBet.Player, pot); err != nil {
+				g.Logger().Printf("payout failed for player %d: %v", userBet.Player.Id, err)
+			}
+
+			if err := gameManager.store.Save(*g); err != nil {
+				g.Logger().Printf("failed to save game history: %v", err)
+			}
+
 			gameManager.events <- GameEvent{
 				Type:   "winner-picked",
 				Game:   *g,
 				Player: userBet.Player,
-				Amount: g.GetTotalPrice() - userBet.GetTotalBet(),
+				Amount: netWinnings.Dollars(),
 			}
+
+			return userBet
 		}
 	}
+
+	return nil
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber"
+)
+
+// RegisterGameHistoryRoutes wires the read-only game history API onto
+// app, backed by store: GET /games, GET /games/:id, and
+// GET /players/:id/games.
+func RegisterGameHistoryRoutes(app *fiber.App, store GameStore) {
+	app.Get("/games", func(c *fiber.Ctx) {
+		games, err := store.List(GameFilter{})
+		if err != nil {
+			c.Status(500).JSON(map[string]string{"error": err.Error()})
+			return
+		}
+		c.JSON(games)
+	})
+
+	app.Get("/games/:id", func(c *fiber.Ctx) {
+		id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+		if err != nil {
+			c.Status(400).JSON(map[string]string{"error": "invalid game id"})
+			return
+		}
+
+		game, err := store.Get(id)
+		if err != nil {
+			c.Status(404).JSON(map[string]string{"error": err.Error()})
+			return
+		}
+		c.JSON(game)
+	})
+
+	app.Get("/players/:id/games", func(c *fiber.Ctx) {
+		playerID, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			c.Status(400).JSON(map[string]string{"error": "invalid player id"})
+			return
+		}
+
+		games, err := store.List(GameFilter{PlayerID: &playerID})
+		if err != nil {
+			c.Status(500).JSON(map[string]string{"error": err.Error()})
+			return
+		}
+		c.JSON(games)
+	})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// gameLogSink is where every Game.Logger() writes to. Defaults to
+// stderr, same as the standard logger it replaces.
+var (
+	gameLogMutex sync.Mutex
+	gameLogSink  io.Writer = os.Stderr
+	gameLogJSON  bool
+)
+
+// SetLogSink redirects all game-scoped logging to w. Safe to call at
+// any time; it takes effect for subsequent log lines.
+func SetLogSink(w io.Writer) {
+	gameLogMutex.Lock()
+	defer gameLogMutex.Unlock()
+	gameLogSink = w
+}
+
+// SetLogJSON switches game-scoped logging between the default
+// "[GAME id|state] ..." text lines and one JSON object per line, so ops
+// tooling can grep/parse by game_id.
+func SetLogJSON(enabled bool) {
+	gameLogMutex.Lock()
+	defer gameLogMutex.Unlock()
+	gameLogJSON = enabled
+}
+
+// GameLogger prefixes every line with the owning game's ID and current
+// round state, so concurrent games won't produce interleaved,
+// untraceable output.
+type GameLogger struct {
+	game *Game
+}
+
+// Logger returns a logger scoped to this game.
+func (g *Game) Logger() *GameLogger {
+	return &GameLogger{game: g}
+}
+
+func (l *GameLogger) Printf(format string, args ...interface{}) {
+	l.write(fmt.Sprintf(format, args...))
+}
+
+func (l *GameLogger) Print(args ...interface{}) {
+	l.write(fmt.Sprint(args...))
+}
+
+func (l *GameLogger) Println(args ...interface{}) {
+	l.write(fmt.Sprintln(args...))
+}
+
+func (l *GameLogger) write(msg string) {
+	msg = strings.TrimSuffix(msg, "\n")
+
+	gameLogMutex.Lock()
+	sink, asJSON := gameLogSink, gameLogJSON
+	gameLogMutex.Unlock()
+
+	if asJSON {
+		line, err := json.Marshal(map[string]interface{}{
+			"game_id": l.game.ID,
+			"state":   stateName(l.game.State),
+			"msg":     msg,
+		})
+		if err != nil {
+			log.Printf("[GAME] failed to marshal log line: %v", err)
+			return
+		}
+		fmt.Fprintln(sink, string(line))
+		return
+	}
+
+	fmt.Fprintf(sink, "[GAME %d|%s] %s\n", l.game.ID, stateName(l.game.State), msg)
+}
+
+func stateName(state int) string {
+	switch state {
+	case Idle:
+		return "idle"
+	case InProgress:
+		return "in-progress"
+	case Ended:
+		return "ended"
+	case WinnerPicked:
+		return "winner-picked"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Money represents an amount of US dollars as integer cents, so bet
+// math never loses a fractional dollar to float rounding or
+// truncation. It marshals to/from a plain dollar float at the JSON
+// boundary, same as the API already expects.
+type Money int64
+
+// NewMoney converts a dollar amount, as received at the JSON boundary,
+// into cents, rounding to the nearest cent.
+func NewMoney(dollars float64) Money {
+	return Money(math.Round(dollars * 100))
+}
+
+// Dollars converts back to a dollar amount, for display.
+func (m Money) Dollars() float64 {
+	return float64(m) / 100
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("$%.2f", m.Dollars())
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Dollars())
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var dollars float64
+	if err := json.Unmarshal(data, &dollars); err != nil {
+		return err
+	}
+	*m = NewMoney(dollars)
+	return nil
+}
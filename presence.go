@@ -0,0 +1,60 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultIdleThreshold is how long a player can go without a ping, bet,
+// or other authenticated request before they're considered to have
+// walked away. It must be strictly greater than roundCountdown
+// (game.go): a bettor who places one bet and then just waits out the
+// round is only ever "seen" at bet time, so if the threshold were equal
+// to (or shorter than) the countdown, time.Since(lastSeen) would reach
+// it right as the round ends and every real round would look idle and
+// get refunded.
+const defaultIdleThreshold = roundCountdown + 15*time.Second
+
+// PlayerPresence tracks when each player was last seen, so GameManager
+// can tell a connected player from one who has disconnected mid-round.
+type PlayerPresence struct {
+	mutex         sync.Mutex
+	lastSeen      map[int]time.Time
+	idleThreshold time.Duration
+}
+
+func NewPlayerPresence(idleThreshold time.Duration) *PlayerPresence {
+	return &PlayerPresence{
+		lastSeen:      make(map[int]time.Time),
+		idleThreshold: idleThreshold,
+	}
+}
+
+// Touch records activity for a player - a websocket ping, a bet, or any
+// other authenticated request.
+func (p *PlayerPresence) Touch(playerID int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.lastSeen[playerID] = time.Now()
+}
+
+// IsActive reports whether the player has been seen within the idle
+// threshold. A player never seen at all is not active.
+func (p *PlayerPresence) IsActive(playerID int) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	last, ok := p.lastSeen[playerID]
+	if !ok {
+		return false
+	}
+	return time.Since(last) < p.idleThreshold
+}
+
+// LastAccessTime returns when the player was last seen, and whether
+// they've been seen at all.
+func (p *PlayerPresence) LastAccessTime(playerID int) (time.Time, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	last, ok := p.lastSeen[playerID]
+	return last, ok
+}
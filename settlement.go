@@ -0,0 +1,57 @@
+package main
+
+import "sync"
+
+// Settler moves money for a Game's pot. PlaceBet escrows a player's stake
+// as soon as it's placed, GetWinner pays the winner once a round
+// settles, and RefundGame refunds every stake when a round is
+// cancelled. The default implementation is an in-memory ledger; the
+// build-tagged EVMSettler (settlement_evm.go) has the same methods for
+// an on-chain (Neo/EVM) contract, but sends no transactions and returns
+// an error from every call.
+type Settler interface {
+	Escrow(player *Player, amount Money) error
+	Payout(player *Player, amount Money) error
+	Refund(player *Player, amount Money) error
+}
+
+// MemorySettler is the default Settler. It keeps a running balance per
+// player in memory and never touches anything outside the process -
+// today's implicit "trust the server" model, made explicit behind the
+// Settler interface so it can be swapped out.
+type MemorySettler struct {
+	mutex  sync.Mutex
+	ledger map[int]Money
+}
+
+func NewMemorySettler() *MemorySettler {
+	return &MemorySettler{
+		ledger: make(map[int]Money),
+	}
+}
+
+func (s *MemorySettler) Escrow(player *Player, amount Money) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ledger[player.Id] -= amount
+	return nil
+}
+
+func (s *MemorySettler) Payout(player *Player, amount Money) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ledger[player.Id] += amount
+	return nil
+}
+
+func (s *MemorySettler) Refund(player *Player, amount Money) error {
+	return s.Payout(player, amount)
+}
+
+// BalanceOf returns the player's current ledger balance, mostly useful
+// for tests and for reconciling escrowed-but-unsettled bets.
+func (s *MemorySettler) BalanceOf(playerID int) Money {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.ledger[playerID]
+}
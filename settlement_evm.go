@@ -0,0 +1,45 @@
+//go:build evm
+// +build evm
+
+package main
+
+import "fmt"
+
+// EVMSettler is a Settler for an on-chain betting contract (Neo N3 /
+// EVM-compatible chains), with the escrow/payout/refund methods of the
+// external smart-contract jackpot example. It holds the RPC endpoint,
+// contract address and operator key, but contacts no chain: every
+// method returns an error. It's only compiled in with the "evm" build
+// tag.
+type EVMSettler struct {
+	RPCEndpoint    string
+	ContractAddr   string
+	OperatorKeyHex string
+}
+
+func NewEVMSettler(rpcEndpoint, contractAddr, operatorKeyHex string) *EVMSettler {
+	return &EVMSettler{
+		RPCEndpoint:    rpcEndpoint,
+		ContractAddr:   contractAddr,
+		OperatorKeyHex: operatorKeyHex,
+	}
+}
+
+func (s *EVMSettler) Escrow(player *Player, amount Money) error {
+	return s.call("escrow", player, amount)
+}
+
+func (s *EVMSettler) Payout(player *Player, amount Money) error {
+	return s.call("payout", player, amount)
+}
+
+func (s *EVMSettler) Refund(player *Player, amount Money) error {
+	return s.call("refund", player, amount)
+}
+
+// call stands in for submitting a transaction to the contract method.
+// There is no RPC/ABI client here: it submits nothing and always
+// returns an error naming the method, contract, endpoint and player.
+func (s *EVMSettler) call(method string, player *Player, amount Money) error {
+	return fmt.Errorf("evm settler: %s not wired to %s on %s for player %d (%s)", method, s.ContractAddr, s.RPCEndpoint, player.Id, amount)
+}
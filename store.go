@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// GameFilter narrows GameStore.List; a nil field means "no filter on
+// that column".
+type GameFilter struct {
+	PlayerID *int
+}
+
+// GameStore persists finished games so the history survives a restart,
+// and lets it be queried back out for review, P&L, and stats.
+type GameStore interface {
+	Save(game Game) error
+	Get(id int64) (Game, error)
+	List(filter GameFilter) ([]Game, error)
+}
+
+// MemoryGameStore is the default GameStore: an in-memory map, the same
+// "gone on restart" behavior game history always had. GORMGameStore is
+// the persistent alternative, set with GameManager.SetStore.
+type MemoryGameStore struct {
+	mutex sync.Mutex
+	games map[int64]Game
+}
+
+func NewMemoryGameStore() *MemoryGameStore {
+	return &MemoryGameStore{
+		games: make(map[int64]Game),
+	}
+}
+
+func (s *MemoryGameStore) Save(game Game) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.games[game.ID] = game
+	return nil
+}
+
+func (s *MemoryGameStore) Get(id int64) (Game, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	game, ok := s.games[id]
+	if !ok {
+		return Game{}, fmt.Errorf("game %d not found", id)
+	}
+	return game, nil
+}
+
+func (s *MemoryGameStore) List(filter GameFilter) ([]Game, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	games := make([]Game, 0, len(s.games))
+	for _, game := range s.games {
+		if filter.PlayerID != nil && !game.hasPlayer(*filter.PlayerID) {
+			continue
+		}
+		games = append(games, game)
+	}
+	return games, nil
+}
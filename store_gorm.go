@@ -0,0 +1,188 @@
+package main
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// gameRecord is the GORM row for a finished game; its UserBets/Bets are
+// normalized into their own tables and stitched back together on read.
+type gameRecord struct {
+	ID             int64 `gorm:"primary_key"`
+	NewTime        time.Time
+	StartTime      time.Time
+	EndTime        time.Time
+	Duration       int
+	State          int
+	SeedHash       string
+	ServerSeed     string
+	WinnerPlayerID int
+}
+
+func (gameRecord) TableName() string { return "games" }
+
+type userBetRecord struct {
+	ID          uint  `gorm:"primary_key"`
+	GameID      int64 `gorm:"index"`
+	PlayerID    int
+	PlayerEmail string
+	StartTicket int64
+	EndTicket   int64
+	Share       float64
+}
+
+func (userBetRecord) TableName() string { return "user_bets" }
+
+type betRecord struct {
+	ID        uint `gorm:"primary_key"`
+	UserBetID uint `gorm:"index"`
+	Amount    Money
+	Created   time.Time
+}
+
+func (betRecord) TableName() string { return "bets" }
+
+// GORMGameStore is the persistent GameStore, backed by jinzhu/gorm.
+type GORMGameStore struct {
+	db *gorm.DB
+}
+
+// NewGORMGameStore migrates the game history tables and returns a
+// GameStore backed by db.
+func NewGORMGameStore(db *gorm.DB) (*GORMGameStore, error) {
+	for _, model := range []interface{}{&gameRecord{}, &userBetRecord{}, &betRecord{}} {
+		if err := db.AutoMigrate(model).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &GORMGameStore{db: db}, nil
+}
+
+func (s *GORMGameStore) Save(game Game) error {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	record := gameRecord{
+		ID:             game.ID,
+		NewTime:        game.NewTime,
+		StartTime:      game.StartTime,
+		EndTime:        game.EndTime,
+		Duration:       game.Duration,
+		State:          game.State,
+		SeedHash:       game.SeedHash,
+		ServerSeed:     game.RevealedSeed,
+		WinnerPlayerID: game.WinnerPlayerID,
+	}
+	if err := tx.Save(&record).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, ub := range game.UserBets {
+		ubRecord := userBetRecord{
+			GameID:      game.ID,
+			PlayerID:    ub.Player.Id,
+			PlayerEmail: ub.Player.Email,
+			StartTicket: ub.StartTicket,
+			EndTicket:   ub.EndTicket,
+			Share:       ub.Share,
+		}
+		if err := tx.Create(&ubRecord).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		for _, bet := range ub.Bets {
+			betRec := betRecord{
+				UserBetID: ubRecord.ID,
+				Amount:    bet.Amount,
+				Created:   bet.Created,
+			}
+			if err := tx.Create(&betRec).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func (s *GORMGameStore) Get(id int64) (Game, error) {
+	var record gameRecord
+	if err := s.db.Where("id = ?", id).First(&record).Error; err != nil {
+		return Game{}, err
+	}
+	return s.hydrate(record)
+}
+
+func (s *GORMGameStore) List(filter GameFilter) ([]Game, error) {
+	query := s.db.Model(&gameRecord{})
+
+	if filter.PlayerID != nil {
+		query = query.
+			Joins("JOIN user_bets ON user_bets.game_id = games.id").
+			Where("user_bets.player_id = ?", *filter.PlayerID).
+			Group("games.id")
+	}
+
+	var records []gameRecord
+	if err := query.Order("games.new_time desc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	games := make([]Game, 0, len(records))
+	for _, record := range records {
+		game, err := s.hydrate(record)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+// hydrate reassembles a domain Game (with its UserBets and Bets) from
+// its normalized rows.
+func (s *GORMGameStore) hydrate(record gameRecord) (Game, error) {
+	var ubRecords []userBetRecord
+	if err := s.db.Where("game_id = ?", record.ID).Find(&ubRecords).Error; err != nil {
+		return Game{}, err
+	}
+
+	game := Game{
+		ID:             record.ID,
+		NewTime:        record.NewTime,
+		StartTime:      record.StartTime,
+		EndTime:        record.EndTime,
+		Duration:       record.Duration,
+		State:          record.State,
+		SeedHash:       record.SeedHash,
+		WinnerPlayerID: record.WinnerPlayerID,
+	}
+
+	for _, ubRecord := range ubRecords {
+		var betRecords []betRecord
+		if err := s.db.Where("user_bet_id = ?", ubRecord.ID).Find(&betRecords).Error; err != nil {
+			return Game{}, err
+		}
+
+		bets := make([]*Bet, 0, len(betRecords))
+		for _, b := range betRecords {
+			bets = append(bets, &Bet{Amount: b.Amount, Created: b.Created})
+		}
+
+		game.UserBets = append(game.UserBets, UserBet{
+			Bets:        bets,
+			Player:      &Player{Id: ubRecord.PlayerID, Email: ubRecord.PlayerEmail},
+			StartTicket: ubRecord.StartTicket,
+			EndTicket:   ubRecord.EndTicket,
+			Share:       ubRecord.Share,
+		})
+	}
+
+	return game, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	TournamentOpen     = 0
+	TournamentRunning  = 1
+	TournamentFinished = 2
+)
+
+// TournamentEvent is broadcast on GameManager's event stream as a
+// bracket progresses, alongside the regular GameEvent/CountDownEvent
+// traffic from its underlying matches.
+type TournamentEvent struct {
+	Type       string     `json:"type"`
+	Tournament Tournament `json:"tournament"`
+	Player     *Player    `json:"player,omitempty"`
+}
+
+// Tournament composes a sequence of GameManager matches into a
+// single-elimination bracket: players buy in with a fixed-size bet,
+// the winner of each match advances, and the final winner takes the
+// accumulated pot. Size must be a power of two - there's no bye
+// handling for odd numbers of players.
+type Tournament struct {
+	mutex *sync.Mutex
+
+	ID      int64     `json:"id"`
+	Size    int       `json:"size"`
+	BuyIn   Money     `json:"buyIn"`
+	State   int       `json:"state"`
+	Round   int       `json:"round"`
+	Pot     Money     `json:"pot"`
+	Players []*Player `json:"players"`
+
+	pending   []*Player
+	nextRound []*Player
+	manager   *GameManager
+}
+
+func NewTournament(gm *GameManager, size int, buyIn Money) *Tournament {
+	return &Tournament{
+		mutex:   &sync.Mutex{},
+		ID:      time.Now().UnixNano(),
+		Size:    size,
+		BuyIn:   buyIn,
+		Players: make([]*Player, 0, size),
+		manager: gm,
+	}
+}
+
+// Join enters a player into the bracket. Once Size players have joined,
+// the first round is scheduled automatically.
+func (t *Tournament) Join(player *Player) error {
+	t.mutex.Lock()
+
+	if t.State != TournamentOpen {
+		t.mutex.Unlock()
+		return fmt.Errorf("tournament %d: already started", t.ID)
+	}
+	if len(t.Players) >= t.Size {
+		t.mutex.Unlock()
+		return fmt.Errorf("tournament %d: bracket is full", t.ID)
+	}
+
+	t.Players = append(t.Players, player)
+	t.pending = append(t.pending, player)
+	full := len(t.Players) == t.Size
+
+	if full {
+		t.State = TournamentRunning
+		t.Round = 1
+	}
+
+	t.mutex.Unlock()
+
+	if !full {
+		return nil
+	}
+
+	t.manager.Events() <- TournamentEvent{
+		Type:       "tournament-round",
+		Tournament: *t,
+	}
+
+	go t.playNextMatch()
+	return nil
+}
+
+// playNextMatch pairs the next two pending players of the current round
+// and drives them through a regular GameManager match: NewGame to open
+// it, then two PlaceBet calls (which, as usual, auto-starts the game
+// once both bets land). EndGame calls back into advance() once the
+// match has a winner.
+func (t *Tournament) playNextMatch() {
+	t.mutex.Lock()
+	if len(t.pending) < 2 {
+		t.mutex.Unlock()
+		return
+	}
+	a, b := t.pending[0], t.pending[1]
+	t.pending = t.pending[2:]
+	t.mutex.Unlock()
+
+	t.manager.NewGame()
+	match := t.manager.GetCurrentGame()
+	match.tournament = t
+
+	match.PlaceBet(a, t.BuyIn)
+	match.PlaceBet(b, t.BuyIn)
+}
+
+// advance records a finished match's winner and either schedules the
+// round's next match, promotes the survivors into a fresh round, or -
+// once a single player remains - pays out the accumulated pot and
+// declares the tournament winner.
+func (t *Tournament) advance(g *Game, winner *UserBet) {
+	t.mutex.Lock()
+
+	if winner == nil {
+		t.mutex.Unlock()
+		g.Logger().Printf("tournament %d round %d produced no winner, bracket aborted", t.ID, t.Round)
+		return
+	}
+
+	// Each match already pays its own round winner through the usual
+	// GetWinner/Settler flow; Pot accumulates on top of that as the
+	// bonus the eventual tournament champion takes home.
+	t.Pot += g.GetTotalPrice()
+	t.nextRound = append(t.nextRound, winner.Player)
+
+	if len(t.pending) >= 2 {
+		t.mutex.Unlock()
+
+		t.manager.Events() <- TournamentEvent{
+			Type:       "tournament-advance",
+			Tournament: *t,
+			Player:     winner.Player,
+		}
+
+		go t.playNextMatch()
+		return
+	}
+
+	if len(t.nextRound) == 1 {
+		champion := t.nextRound[0]
+		t.State = TournamentFinished
+		pot := t.Pot
+		t.mutex.Unlock()
+
+		if err := t.manager.settler.Payout(champion, pot); err != nil {
+			g.Logger().Printf("tournament %d payout failed for player %d: %v", t.ID, champion.Id, err)
+		}
+
+		t.manager.Events() <- TournamentEvent{
+			Type:       "tournament-winner",
+			Tournament: *t,
+			Player:     champion,
+		}
+		return
+	}
+
+	t.pending = t.nextRound
+	t.nextRound = nil
+	t.Round++
+	t.mutex.Unlock()
+
+	t.manager.Events() <- TournamentEvent{
+		Type:       "tournament-advance",
+		Tournament: *t,
+		Player:     winner.Player,
+	}
+	t.manager.Events() <- TournamentEvent{
+		Type:       "tournament-round",
+		Tournament: *t,
+	}
+
+	go t.playNextMatch()
+}